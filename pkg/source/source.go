@@ -0,0 +1,211 @@
+// Package source fetches a single dependency's content into a destination
+// directory from a git repository, a sibling path on disk, or a bare URL.
+// It has no fetcher for the central registry.
+package source
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// Spec describes where a single dependency's content comes from: a git
+// repository, a sibling path on disk, or a bare URL. Registry dependencies
+// never become a Spec — they stay on the existing index + semver solver
+// pipeline, which already owns graph resolution and can't be expressed as a
+// single-dependency fetch.
+type Spec struct {
+	Name    string
+	Version string
+	Git     string
+	Branch  string
+	Tag     string
+	Rev     string
+	Path    string
+	URL     string
+	Sha256  string
+}
+
+// Fetcher populates destDir with a dependency's content and reports what
+// version/commit/hash it actually resolved to, so callers can pin it in
+// virus.lock.
+type Fetcher interface {
+	Fetch(ctx context.Context, spec Spec, destDir string) (resolvedVersion string, err error)
+}
+
+// GitFetcher shallow-clones a git repository (a full clone when Rev is set),
+// checking out Rev (if set, taking priority so a pinned commit always wins
+// over a moving branch), else Tag, else Branch.
+type GitFetcher struct{}
+
+func (GitFetcher) Fetch(ctx context.Context, spec Spec, destDir string) (string, error) {
+	if spec.Git == "" {
+		return "", fmt.Errorf("git fetcher requires a git URL")
+	}
+	if err := os.RemoveAll(destDir); err != nil {
+		return "", err
+	}
+	opts := &git.CloneOptions{URL: spec.Git, Depth: 1}
+	switch {
+	case spec.Rev != "":
+		// A specific commit can't be shallow-fetched by hash with a depth of
+		// 1 in all git servers, so clone with full history (every branch),
+		// then pin to the exact commit below.
+		opts.Depth = 0
+	case spec.Tag != "":
+		opts.ReferenceName = plumbing.NewTagReferenceName(spec.Tag)
+	case spec.Branch != "":
+		opts.ReferenceName = plumbing.NewBranchReferenceName(spec.Branch)
+	}
+	repo, err := git.PlainCloneContext(ctx, destDir, false, opts)
+	if err != nil {
+		return "", fmt.Errorf("git clone %s failed: %w", spec.Git, err)
+	}
+	if spec.Rev != "" {
+		wt, err := repo.Worktree()
+		if err != nil {
+			return "", err
+		}
+		if err := wt.Checkout(&git.CheckoutOptions{Hash: plumbing.NewHash(spec.Rev)}); err != nil {
+			return "", fmt.Errorf("git checkout %s failed: %w", spec.Rev, err)
+		}
+		return spec.Rev, nil
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return "", err
+	}
+	return head.Hash().String(), nil
+}
+
+// PathFetcher links a local sibling directory into destDir, falling back to
+// a copy when symlinks aren't available. It skips the work entirely when
+// destDir is already newer than the source, so repeated builds against an
+// unchanged local dependency don't redo it.
+type PathFetcher struct{}
+
+func (PathFetcher) Fetch(ctx context.Context, spec Spec, destDir string) (string, error) {
+	if spec.Path == "" {
+		return "", fmt.Errorf("path fetcher requires a path")
+	}
+	info, err := os.Stat(spec.Path)
+	if err != nil {
+		return "", err
+	}
+	if destInfo, err := os.Lstat(destDir); err == nil && !destInfo.ModTime().Before(info.ModTime()) {
+		return fmt.Sprintf("local@%d", info.ModTime().Unix()), nil
+	}
+	if err := os.RemoveAll(destDir); err != nil {
+		return "", err
+	}
+	abs, err := filepath.Abs(spec.Path)
+	if err != nil {
+		return "", err
+	}
+	if err := os.Symlink(abs, destDir); err != nil {
+		if err := copyTree(abs, destDir); err != nil {
+			return "", fmt.Errorf("failed to copy %s: %w", spec.Path, err)
+		}
+	}
+	return fmt.Sprintf("local@%d", info.ModTime().Unix()), nil
+}
+
+// TarballFetcher downloads a dependency from a direct URL, outside the
+// registry, verifying it against the pinned sha256 when one is given.
+type TarballFetcher struct{}
+
+func (TarballFetcher) Fetch(ctx context.Context, spec Spec, destDir string) (string, error) {
+	if spec.URL == "" {
+		return "", fmt.Errorf("tarball fetcher requires a url")
+	}
+	dest := filepath.Join(destDir, filepath.Base(spec.URL))
+	if err := downloadHTTP(ctx, spec.URL, dest); err != nil {
+		return "", err
+	}
+	sum, err := hashFile(dest)
+	if err != nil {
+		return "", err
+	}
+	if spec.Sha256 != "" && sum != spec.Sha256 {
+		return "", fmt.Errorf("checksum mismatch for %s: expected %s, got %s", spec.URL, spec.Sha256, sum)
+	}
+	return sum, nil
+}
+
+func downloadHTTP(ctx context.Context, url, dest string) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d fetching %s", resp.StatusCode, url)
+	}
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	_, err = io.Copy(out, resp.Body)
+	return err
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func verifyChecksum(path, expected string) error {
+	sum, err := hashFile(path)
+	if err != nil {
+		return err
+	}
+	if sum != expected {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", path, expected, sum)
+	}
+	return nil
+}
+
+func copyTree(src, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if info.IsDir() {
+			return os.MkdirAll(target, info.Mode())
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, data, info.Mode())
+	})
+}
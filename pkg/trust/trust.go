@@ -0,0 +1,146 @@
+// Package trust manages the local keyring of maintainer PGP public keys used
+// to verify the index and library artifacts that virus downloads, and
+// performs detached-signature checks against it.
+package trust
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/openpgp"
+)
+
+// KeyringDir returns the directory that holds trusted public keys, creating
+// it if necessary.
+func KeyringDir() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(configDir, "virus", "keyring")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+type KeyInfo struct {
+	Fingerprint string
+	Identities  []string
+}
+
+// AddKey imports the armored public key at path into the keyring.
+func AddKey(path string) (KeyInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return KeyInfo{}, err
+	}
+	defer f.Close()
+	entities, err := openpgp.ReadArmoredKeyRing(f)
+	if err != nil {
+		return KeyInfo{}, fmt.Errorf("invalid public key: %w", err)
+	}
+	if len(entities) == 0 {
+		return KeyInfo{}, fmt.Errorf("no keys found in %s", path)
+	}
+	entity := entities[0]
+	info := keyInfoOf(entity)
+	dir, err := KeyringDir()
+	if err != nil {
+		return KeyInfo{}, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return KeyInfo{}, err
+	}
+	dest := filepath.Join(dir, info.Fingerprint+".asc")
+	if err := os.WriteFile(dest, data, 0644); err != nil {
+		return KeyInfo{}, err
+	}
+	return info, nil
+}
+
+// RemoveKey deletes a key from the keyring by fingerprint.
+func RemoveKey(fingerprint string) error {
+	dir, err := KeyringDir()
+	if err != nil {
+		return err
+	}
+	return os.Remove(filepath.Join(dir, fingerprint+".asc"))
+}
+
+// ListKeys returns every key currently in the keyring.
+func ListKeys() ([]KeyInfo, error) {
+	entities, err := loadEntities()
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]KeyInfo, 0, len(entities))
+	for _, entity := range entities {
+		keys = append(keys, keyInfoOf(entity))
+	}
+	return keys, nil
+}
+
+// LoadKeyring reads every stored public key into a single entity list
+// suitable for signature verification.
+func LoadKeyring() (openpgp.EntityList, error) {
+	return loadEntities()
+}
+
+func loadEntities() (openpgp.EntityList, error) {
+	dir, err := KeyringDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var keyring openpgp.EntityList
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		f, err := os.Open(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		entities, err := openpgp.ReadArmoredKeyRing(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %s: %w", entry.Name(), err)
+		}
+		keyring = append(keyring, entities...)
+	}
+	return keyring, nil
+}
+
+func keyInfoOf(entity *openpgp.Entity) KeyInfo {
+	info := KeyInfo{Fingerprint: fmt.Sprintf("%X", entity.PrimaryKey.Fingerprint)}
+	for _, ident := range entity.Identities {
+		info.Identities = append(info.Identities, ident.Name)
+	}
+	return info
+}
+
+// VerifyDetached checks sigPath as a detached signature over dataPath
+// against the keyring, returning the signer's fingerprint on success.
+func VerifyDetached(dataPath, sigPath string, keyring openpgp.EntityList) (string, error) {
+	data, err := os.Open(dataPath)
+	if err != nil {
+		return "", err
+	}
+	defer data.Close()
+	sig, err := os.Open(sigPath)
+	if err != nil {
+		return "", err
+	}
+	defer sig.Close()
+	signer, err := openpgp.CheckArmoredDetachedSignature(keyring, data, sig)
+	if err != nil {
+		return "", fmt.Errorf("signature verification failed: %w", err)
+	}
+	return fmt.Sprintf("%X", signer.PrimaryKey.Fingerprint), nil
+}
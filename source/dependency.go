@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// DependencySpec is either a plain version constraint (the existing
+// behavior, e.g. "^1.2") or an inline table naming a git repository, a local
+// path, or a direct URL to fetch from instead of the central index.
+type DependencySpec struct {
+	Version string
+	Git     string
+	Branch  string
+	Tag     string
+	Rev     string
+	Path    string
+	URL     string
+	Sha256  string
+}
+
+// isSourceBacked reports whether spec bypasses the registry entirely.
+func (d DependencySpec) isSourceBacked() bool {
+	return d.Git != "" || d.Path != "" || d.URL != ""
+}
+
+// UnmarshalTOML accepts either a bare string ("^1.2") or a table
+// ({ git = "...", branch = "..." }, { path = "..." }, { url = "...", sha256 = "..." }).
+//
+// go-toml/v2 hands custom unmarshalers the raw TOML bytes for just this
+// value, not a pre-decoded Go value, so re-parse it through the same
+// library by wrapping it as the right-hand side of a throwaway key; that
+// reuses the real TOML grammar instead of hand-rolling one for strings and
+// inline tables.
+func (d *DependencySpec) UnmarshalTOML(data []byte) error {
+	var wrapper struct {
+		Value interface{} `toml:"value"`
+	}
+	if err := toml.Unmarshal(append([]byte("value = "), data...), &wrapper); err != nil {
+		return fmt.Errorf("invalid dependency spec: %w", err)
+	}
+	switch v := wrapper.Value.(type) {
+	case string:
+		d.Version = v
+		return nil
+	case map[string]interface{}:
+		for key, raw := range v {
+			s, ok := raw.(string)
+			if !ok {
+				return fmt.Errorf("dependency field %q must be a string", key)
+			}
+			switch key {
+			case "git":
+				d.Git = s
+			case "branch":
+				d.Branch = s
+			case "tag":
+				d.Tag = s
+			case "rev":
+				d.Rev = s
+			case "path":
+				d.Path = s
+			case "url":
+				d.URL = s
+			case "sha256":
+				d.Sha256 = s
+			default:
+				return fmt.Errorf("unknown dependency field %q", key)
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid dependency spec: %#v", wrapper.Value)
+	}
+}
+
+// MarshalTOML renders a plain version as a bare string, and a source-backed
+// spec as an inline table with only the fields that are set.
+func (d DependencySpec) MarshalTOML() ([]byte, error) {
+	if !d.isSourceBacked() {
+		return []byte(fmt.Sprintf("%q", d.Version)), nil
+	}
+	table := make(map[string]string, 6)
+	add := func(key, value string) {
+		if value != "" {
+			table[key] = value
+		}
+	}
+	add("git", d.Git)
+	add("branch", d.Branch)
+	add("tag", d.Tag)
+	add("rev", d.Rev)
+	add("path", d.Path)
+	add("url", d.URL)
+	add("sha256", d.Sha256)
+	return marshalInlineTable(table)
+}
+
+// marshalInlineTable renders a flat string-valued map as TOML inline table
+// syntax ({ k = "v", ... }), in a stable key order.
+func marshalInlineTable(table map[string]string) ([]byte, error) {
+	order := []string{"git", "branch", "tag", "rev", "path", "url", "sha256"}
+	out := "{ "
+	first := true
+	for _, key := range order {
+		value, ok := table[key]
+		if !ok {
+			continue
+		}
+		if !first {
+			out += ", "
+		}
+		out += fmt.Sprintf("%s = %q", key, value)
+		first = false
+	}
+	out += " }"
+	return []byte(out), nil
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// constraintSource records who asked for a given constraint, so a conflict
+// can be reported as the exact chain that produced it (e.g.
+// "A -> B ^0.3"). requirer is the requiring library's name, or "(root)" for
+// Project.toml; the requirer's own resolved version is not recorded.
+type constraintSource struct {
+	requirer string
+	spec     string
+}
+
+type resolveConflictError struct {
+	library string
+	chain   []constraintSource
+}
+
+func (e *resolveConflictError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "no version of %s satisfies every constraint:\n", e.library)
+	for _, c := range e.chain {
+		fmt.Fprintf(&b, "  %s -> %s %s\n", c.requirer, e.library, c.spec)
+	}
+	return b.String()
+}
+
+// graphSolver performs a BFS over the dependency graph rooted at the
+// project's direct dependencies, picking the highest version of each
+// library that satisfies every constraint accumulated against it so far.
+// If a later edge invalidates an already-picked version, it re-picks against
+// the updated constraint set; if no version satisfies everything, it reports
+// the exact constraint chain instead of silently choosing one.
+type graphSolver struct {
+	index       LibraryIndex
+	resolved    map[string]*Version
+	constraints map[string][]constraintSource
+}
+
+// resolveDependencyGraph builds the full transitive dependency graph for
+// rootDeps and returns the chosen version of every library in it.
+func resolveDependencyGraph(index LibraryIndex, rootDeps map[string]string) (map[string]*Version, error) {
+	s := &graphSolver{
+		index:       index,
+		resolved:    make(map[string]*Version),
+		constraints: make(map[string][]constraintSource),
+	}
+	queue := make([]string, 0, len(rootDeps))
+	for _, name := range sortedKeys(rootDeps) {
+		s.constraints[name] = append(s.constraints[name], constraintSource{requirer: "(root)", spec: rootDeps[name]})
+		queue = append(queue, name)
+	}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		previous := s.resolved[name]
+		picked, changed, err := s.pick(name)
+		if err != nil {
+			return nil, err
+		}
+		if !changed {
+			continue
+		}
+		if previous != nil {
+			s.retract(name, previous)
+		}
+		s.resolved[name] = picked
+		for _, dep := range sortedKeys(picked.Dependencies) {
+			s.constraints[dep] = append(s.constraints[dep], constraintSource{requirer: name, spec: picked.Dependencies[dep]})
+			queue = append(queue, dep)
+		}
+	}
+	return s.resolved, nil
+}
+
+// retract removes every constraint that name contributed on behalf of prev,
+// the version it held before being re-picked. Without this, a dependency
+// that loses a requirer (or has its constraint loosened) when name's pick
+// changes keeps satisfying the stale constraint forever, which can report a
+// false conflict even though a valid resolution exists.
+func (s *graphSolver) retract(name string, prev *Version) {
+	for dep := range prev.Dependencies {
+		kept := s.constraints[dep][:0]
+		for _, c := range s.constraints[dep] {
+			if c.requirer != name {
+				kept = append(kept, c)
+			}
+		}
+		s.constraints[dep] = kept
+	}
+}
+
+// sortedKeys returns m's keys in sorted order, so graph traversal (and
+// therefore which version gets picked when multiple satisfy a constraint
+// equally) doesn't depend on Go's randomized map iteration order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// pick finds the highest version of name satisfying every accumulated
+// constraint. changed reports whether this differs from what was already
+// resolved (or is a first resolution), so the caller knows whether to
+// re-walk its dependencies.
+func (s *graphSolver) pick(name string) (picked *Version, changed bool, err error) {
+	lib := findLibrary(s.index, name)
+	if lib == nil {
+		return nil, false, fmt.Errorf("library not found: %s", name)
+	}
+	for _, candidate := range sortedVersionsDesc(lib.Versions) {
+		if s.satisfies(name, candidate) {
+			current, ok := s.resolved[name]
+			if ok && current.Version == candidate.Version {
+				return current, false, nil
+			}
+			v := candidate
+			return &v, true, nil
+		}
+	}
+	return nil, false, &resolveConflictError{library: name, chain: s.constraints[name]}
+}
+
+func (s *graphSolver) satisfies(name string, version Version) bool {
+	v, err := parseSemVersion(version.Version)
+	if err != nil {
+		return false
+	}
+	for _, c := range s.constraints[name] {
+		constraint, err := parseConstraint(c.spec)
+		if err != nil {
+			return false
+		}
+		if !constraint.Matches(v) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResolveDependencyGraph(t *testing.T) {
+	index := LibraryIndex{Libraries: []Library{
+		{Name: "a", Versions: []Version{
+			{Version: "1.2.0", URL: "https://example.test/a-1.2.0", Dependencies: map[string]string{"b": "^0.3"}},
+		}},
+		{Name: "b", Versions: []Version{
+			{Version: "0.2.0", URL: "https://example.test/b-0.2.0"},
+			{Version: "0.3.0", URL: "https://example.test/b-0.3.0"},
+			{Version: "0.3.5", URL: "https://example.test/b-0.3.5"},
+		}},
+	}}
+	resolved, err := resolveDependencyGraph(index, map[string]string{"a": "^1.0", "b": ">=0.3"})
+	if err != nil {
+		t.Fatalf("resolveDependencyGraph: unexpected error: %v", err)
+	}
+	if got := resolved["a"].Version; got != "1.2.0" {
+		t.Errorf("resolved[a] = %q, want 1.2.0", got)
+	}
+	if got := resolved["b"].Version; got != "0.3.5" {
+		t.Errorf("resolved[b] = %q, want 0.3.5 (highest version satisfying both ^0.3 and >=0.3)", got)
+	}
+}
+
+func TestResolveDependencyGraphConflict(t *testing.T) {
+	index := LibraryIndex{Libraries: []Library{
+		{Name: "a", Versions: []Version{
+			{Version: "1.2.0", URL: "https://example.test/a-1.2.0", Dependencies: map[string]string{"b": "^1.0"}},
+		}},
+		{Name: "c", Versions: []Version{
+			{Version: "1.0.0", URL: "https://example.test/c-1.0.0", Dependencies: map[string]string{"b": "^2.0"}},
+		}},
+		{Name: "b", Versions: []Version{
+			{Version: "1.5.0", URL: "https://example.test/b-1.5.0"},
+			{Version: "2.5.0", URL: "https://example.test/b-2.5.0"},
+		}},
+	}}
+	_, err := resolveDependencyGraph(index, map[string]string{"a": "^1.0", "c": "^1.0"})
+	if err == nil {
+		t.Fatal("resolveDependencyGraph: expected a conflict error, got nil")
+	}
+	conflict, ok := err.(*resolveConflictError)
+	if !ok {
+		t.Fatalf("resolveDependencyGraph: error is %T, want *resolveConflictError", err)
+	}
+	if conflict.library != "b" {
+		t.Errorf("conflict.library = %q, want %q", conflict.library, "b")
+	}
+	msg := conflict.Error()
+	if !strings.Contains(msg, "a -> b ^1.0") || !strings.Contains(msg, "c -> b ^2.0") {
+		t.Errorf("conflict message %q missing expected constraint chain entries", msg)
+	}
+}
+
+// TestResolveDependencyGraphRetractsStaleConstraints covers the scenario
+// where a re-pick downgrades a library's major version: the constraint its
+// old pick placed on a shared transitive dependency must be retracted, or
+// that dependency is left satisfying both the old and new constraint
+// forever and a valid resolution is reported as a false conflict.
+func TestResolveDependencyGraphRetractsStaleConstraints(t *testing.T) {
+	index := LibraryIndex{Libraries: []Library{
+		{Name: "a", Versions: []Version{
+			{Version: "2.0.0", URL: "https://example.test/a-2.0.0", Dependencies: map[string]string{"c": "^2.0"}},
+			{Version: "1.0.0", URL: "https://example.test/a-1.0.0", Dependencies: map[string]string{"c": "^1.0"}},
+		}},
+		{Name: "b", Versions: []Version{
+			{Version: "1.0.0", URL: "https://example.test/b-1.0.0", Dependencies: map[string]string{"a": "^1.0"}},
+		}},
+		{Name: "c", Versions: []Version{
+			{Version: "1.5.0", URL: "https://example.test/c-1.5.0"},
+			{Version: "2.5.0", URL: "https://example.test/c-2.5.0"},
+		}},
+	}}
+	resolved, err := resolveDependencyGraph(index, map[string]string{"a": "*", "b": "*"})
+	if err != nil {
+		t.Fatalf("resolveDependencyGraph: unexpected error: %v (a valid resolution exists: a=1.0.0, b=1.0.0, c=1.5.0)", err)
+	}
+	if got := resolved["a"].Version; got != "1.0.0" {
+		t.Errorf("resolved[a] = %q, want 1.0.0 (b ^1.0 forces a down from the root's first pick of 2.0.0)", got)
+	}
+	if got := resolved["c"].Version; got != "1.5.0" {
+		t.Errorf("resolved[c] = %q, want 1.5.0 (a's stale c ^2.0 constraint from its discarded 2.0.0 pick must be retracted)", got)
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pterm/pterm"
+)
+
+const lockTOML = "virus.lock"
+
+// LockFile pins the exact resolved version, source URL and artifact hash for
+// every dependency, so `virus compile` can reproduce a build without
+// re-resolving against the live index.
+type LockFile struct {
+	Dependencies map[string]LockedDependency `toml:"dependencies"`
+}
+
+type LockedDependency struct {
+	Version  string `toml:"version,omitempty"`
+	URL      string `toml:"url,omitempty"`
+	Sha256   string `toml:"sha256,omitempty"`
+	SignedBy string `toml:"signed_by,omitempty"`
+	Git      string `toml:"git,omitempty"`
+	Rev      string `toml:"rev,omitempty"`
+	Path     string `toml:"path,omitempty"`
+}
+
+func loadLock() (LockFile, bool, error) {
+	var lock LockFile
+	data, err := os.ReadFile(lockTOML)
+	if os.IsNotExist(err) {
+		return lock, false, nil
+	}
+	if err != nil {
+		return lock, false, err
+	}
+	if err := toml.Unmarshal(data, &lock); err != nil {
+		return lock, false, err
+	}
+	return lock, true, nil
+}
+
+func saveLock(lock LockFile) error {
+	data, err := toml.Marshal(lock)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(lockTOML, data, 0644)
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// resolveAndLockGraph solves the full transitive dependency graph for
+// rootDeps, downloading each resolved library to a scratch file to hash it,
+// without leaving anything behind in the project tree.
+func resolveAndLockGraph(index LibraryIndex, rootDeps map[string]string, trustMode string) (LockFile, error) {
+	resolved, err := resolveDependencyGraph(index, rootDeps)
+	if err != nil {
+		return LockFile{}, err
+	}
+	lock := LockFile{Dependencies: make(map[string]LockedDependency)}
+	for name, version := range resolved {
+		pterm.Info.Println("Resolved", name, version.Version)
+		tmpFile, err := os.CreateTemp("", "virus-hash-*")
+		if err != nil {
+			return LockFile{}, err
+		}
+		tmpPath := tmpFile.Name()
+		tmpFile.Close()
+		if err := downloadWithProgress(version.URL, tmpPath, version.SignedBy, trustMode, nil); err != nil {
+			os.Remove(tmpPath)
+			return LockFile{}, fmt.Errorf("failed to download %s %s: %w", name, version.Version, err)
+		}
+		sum, err := hashFile(tmpPath)
+		os.Remove(tmpPath)
+		if err != nil {
+			return LockFile{}, err
+		}
+		lock.Dependencies[name] = LockedDependency{
+			Version:  version.Version,
+			URL:      version.URL,
+			Sha256:   sum,
+			SignedBy: version.SignedBy,
+		}
+	}
+	return lock, nil
+}
+
+func lockProject() {
+	pterm.DefaultSection.Println("Locking dependencies")
+	config, err := loadConfig()
+	if err != nil {
+		pterm.Error.Println(err)
+		os.Exit(1)
+	}
+	registryDeps, sourcedDeps := splitDependencies(config.Dependencies)
+	trustMode := trustModeOf(config)
+	index, err := downloadIndex(trustMode)
+	if err != nil {
+		pterm.Error.Println("Failed to download index:", err)
+		os.Exit(1)
+	}
+	lock, err := resolveAndLockGraph(index, registryDeps, trustMode)
+	if err != nil {
+		pterm.Error.Println(err)
+		os.Exit(1)
+	}
+	if len(sourcedDeps) > 0 {
+		previous, _, err := loadLock()
+		if err != nil {
+			pterm.Error.Println("Failed to read virus.lock:", err)
+			os.Exit(1)
+		}
+		scratch, err := os.MkdirTemp("", "virus-src-*")
+		if err != nil {
+			pterm.Error.Println(err)
+			os.Exit(1)
+		}
+		defer os.RemoveAll(scratch)
+		for name, spec := range sourcedDeps {
+			pinned, hasPinned := previous.Dependencies[name]
+			locked, err := fetchSourceDependency(context.Background(), name, spec, filepath.Join(scratch, name), pinned, hasPinned)
+			if err != nil {
+				pterm.Error.Println(err)
+				os.Exit(1)
+			}
+			lock.Dependencies[name] = locked
+		}
+	}
+	if err := saveLock(lock); err != nil {
+		pterm.Error.Println("Failed to write virus.lock:", err)
+		os.Exit(1)
+	}
+	pterm.Success.Println("Wrote virus.lock")
+}
+
+// updateProject re-resolves every registry dependency against the live index
+// and rewrites virus.lock, the same as running `virus lock`. Git dependencies
+// keep the commit already pinned in virus.lock; they do not move to their
+// branch's current head.
+func updateProject() {
+	pterm.DefaultSection.Println("Updating locked dependencies")
+	lockProject()
+}
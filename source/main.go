@@ -35,13 +35,22 @@ const (
 var binPath string
 
 type Config struct {
-	Package      Package           `toml:"package"`
-	Dependencies map[string]string `toml:"dependencies"`
+	Package      Package                   `toml:"package"`
+	Dependencies map[string]DependencySpec `toml:"dependencies"`
+	Trust        string                    `toml:"trust,omitempty"`
 }
 
 type Package struct {
-	Name    string `toml:"name"`
-	Version string `toml:"version"`
+	Name        string            `toml:"name"`
+	Version     string            `toml:"version"`
+	Maintainer  string            `toml:"maintainer,omitempty"`
+	License     string            `toml:"license,omitempty"`
+	Description string            `toml:"description,omitempty"`
+	Section     string            `toml:"section,omitempty"`
+	Depends     []string          `toml:"depends,omitempty"`
+	Conflicts   []string          `toml:"conflicts,omitempty"`
+	Targets     []string          `toml:"targets,omitempty"`
+	Contents    map[string]string `toml:"contents,omitempty"`
 }
 
 type LibraryIndex struct {
@@ -54,8 +63,10 @@ type Library struct {
 }
 
 type Version struct {
-	Version string `json:"version"`
-	URL     string `json:"url"`
+	Version      string            `json:"version"`
+	URL          string            `json:"url"`
+	SignedBy     string            `json:"signed_by,omitempty"`
+	Dependencies map[string]string `json:"dependencies,omitempty"`
 }
 
 func init() {
@@ -88,24 +99,101 @@ func main() {
 		},
 	}
 
+	var addGit, addBranch, addTag, addRev, addPath, addURL, addSha256 string
 	var addCmd = &cobra.Command{
 		Use:   "add [library]",
 		Short: "Add a dependency",
+		Long:  "Add a dependency resolved from the index, or pass --git/--path/--url to fetch it from elsewhere instead.",
 		Args:  cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			addDependency(args[0])
+			spec := DependencySpec{
+				Version: "*",
+				Git:     addGit,
+				Branch:  addBranch,
+				Tag:     addTag,
+				Rev:     addRev,
+				Path:    addPath,
+				URL:     addURL,
+				Sha256:  addSha256,
+			}
+			if spec.isSourceBacked() {
+				spec.Version = ""
+			}
+			addDependency(args[0], spec)
 		},
 	}
+	addCmd.Flags().StringVar(&addGit, "git", "", "fetch from a git repository instead of the index")
+	addCmd.Flags().StringVar(&addBranch, "branch", "", "git branch to track (with --git)")
+	addCmd.Flags().StringVar(&addTag, "tag", "", "git tag to pin (with --git)")
+	addCmd.Flags().StringVar(&addRev, "rev", "", "git commit to pin (with --git)")
+	addCmd.Flags().StringVar(&addPath, "path", "", "use a local sibling path instead of the index")
+	addCmd.Flags().StringVar(&addURL, "url", "", "fetch from a direct URL instead of the index")
+	addCmd.Flags().StringVar(&addSha256, "sha256", "", "expected sha256 of the artifact at --url")
 
+	var jobs int
 	var compileCmd = &cobra.Command{
 		Use:   "compile",
 		Short: "Compile the project",
 		Run: func(cmd *cobra.Command, args []string) {
-			compileProject()
+			compileProject(jobs)
+		},
+	}
+	compileCmd.Flags().IntVarP(&jobs, "jobs", "j", runtime.NumCPU(), "number of dependencies to download and compile in parallel")
+
+	var lockCmd = &cobra.Command{
+		Use:   "lock",
+		Short: "Resolve dependencies and write virus.lock",
+		Run: func(cmd *cobra.Command, args []string) {
+			lockProject()
+		},
+	}
+
+	var updateCmd = &cobra.Command{
+		Use:   "update",
+		Short: "Re-resolve dependencies against the index and rewrite virus.lock",
+		Run: func(cmd *cobra.Command, args []string) {
+			updateProject()
+		},
+	}
+
+	var packageCmd = &cobra.Command{
+		Use:   "package",
+		Short: "Build native OS packages (.deb, .rpm, .apk) from the compiled binary",
+		Run: func(cmd *cobra.Command, args []string) {
+			packageCommand()
+		},
+	}
+
+	var keyCmd = &cobra.Command{
+		Use:   "key",
+		Short: "Manage the local PGP keyring used to verify downloads",
+	}
+	var keyAddCmd = &cobra.Command{
+		Use:   "add <path-to-public-key.asc>",
+		Short: "Add a maintainer's public key to the keyring",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			keyAdd(args[0])
+		},
+	}
+	var keyRemoveCmd = &cobra.Command{
+		Use:   "remove <fingerprint>",
+		Short: "Remove a key from the keyring",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			keyRemove(args[0])
+		},
+	}
+	var keyListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List trusted keys",
+		Run: func(cmd *cobra.Command, args []string) {
+			keyList()
 		},
 	}
+	keyCmd.AddCommand(keyAddCmd, keyRemoveCmd, keyListCmd)
 
-	rootCmd.AddCommand(initCmd, addCmd, compileCmd)
+	rootCmd.AddCommand(initCmd, addCmd, compileCmd, lockCmd, updateCmd, packageCmd, keyCmd)
 	if err := rootCmd.Execute(); err != nil {
 		pterm.Error.Println(err)
 		os.Exit(1)
@@ -119,7 +207,7 @@ func initProject() {
 			Name:    "myproject",
 			Version: "0.1.0",
 		},
-		Dependencies: make(map[string]string),
+		Dependencies: make(map[string]DependencySpec),
 	}
 	data, err := toml.Marshal(config)
 	if err != nil {
@@ -145,23 +233,73 @@ func initProject() {
 	pterm.Success.Println("Project initialized")
 }
 
-func addDependency(lib string) {
+func addDependency(lib string, spec DependencySpec) {
 	pterm.DefaultSection.Println("Adding dependency:", lib)
 	config, err := loadConfig()
 	if err != nil {
 		pterm.Error.Println(err)
 		os.Exit(1)
 	}
-	config.Dependencies[lib] = "*"
+	if config.Dependencies == nil {
+		config.Dependencies = make(map[string]DependencySpec)
+	}
+	config.Dependencies[lib] = spec
 	if err := saveConfig(config); err != nil {
 		pterm.Error.Println(err)
 		os.Exit(1)
 	}
+	trustMode := trustModeOf(config)
+	registryDeps, _ := splitDependencies(config.Dependencies)
+
+	lockFile, _, err := loadLock()
+	if err != nil {
+		pterm.Error.Println("Failed to read virus.lock:", err)
+		os.Exit(1)
+	}
+	if lockFile.Dependencies == nil {
+		lockFile.Dependencies = make(map[string]LockedDependency)
+	}
+	if len(registryDeps) > 0 {
+		index, err := downloadIndex(trustMode)
+		if err != nil {
+			pterm.Error.Println("Failed to download index:", err)
+			os.Exit(1)
+		}
+		resolved, err := resolveAndLockGraph(index, registryDeps, trustMode)
+		if err != nil {
+			pterm.Error.Println(err)
+			os.Exit(1)
+		}
+		for name, locked := range resolved.Dependencies {
+			lockFile.Dependencies[name] = locked
+		}
+	}
+	if spec.isSourceBacked() {
+		scratch, err := os.MkdirTemp("", "virus-src-*")
+		if err != nil {
+			pterm.Error.Println(err)
+			os.Exit(1)
+		}
+		defer os.RemoveAll(scratch)
+		locked, err := fetchSourceDependency(context.Background(), lib, spec, filepath.Join(scratch, lib), LockedDependency{}, false)
+		if err != nil {
+			pterm.Error.Println(err)
+			os.Exit(1)
+		}
+		lockFile.Dependencies[lib] = locked
+	}
+	if err := saveLock(lockFile); err != nil {
+		pterm.Error.Println("Failed to update virus.lock:", err)
+		os.Exit(1)
+	}
 	pterm.Success.Println("Dependency added")
 }
 
-func compileProject() {
+func compileProject(jobs int) {
 	pterm.DefaultSection.Println("Compiling Vira project")
+	if jobs < 1 {
+		jobs = runtime.NumCPU()
+	}
 	config, err := loadConfig()
 	if err != nil {
 		pterm.Error.Println(err)
@@ -191,13 +329,25 @@ func compileProject() {
 		pterm.Error.Println("Failed to create deps dir:", err)
 		os.Exit(1)
 	}
-	index, err := downloadIndex()
+	registryDeps, sourcedDeps := splitDependencies(config.Dependencies)
+	trustMode := trustModeOf(config)
+	lock, hasLock, err := loadLock()
 	if err != nil {
-		pterm.Error.Println("Failed to download index:", err)
+		pterm.Error.Println("Failed to read virus.lock:", err)
 		os.Exit(1)
 	}
-	depPaths := []string{}
-	objectFilesContainer := []string{}
+	if !hasLock {
+		index, err := downloadIndex(trustMode)
+		if err != nil {
+			pterm.Error.Println("Failed to download index:", err)
+			os.Exit(1)
+		}
+		lock, err = resolveAndLockGraph(index, registryDeps, trustMode)
+		if err != nil {
+			pterm.Error.Println(err)
+			os.Exit(1)
+		}
+	}
 	ctx := context.Background()
 	runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
 	if runtimeDir == "" {
@@ -245,41 +395,53 @@ func compileProject() {
 		pterm.Error.Println("apk add failed:", out, err)
 		os.Exit(1)
 	}
-	for name, versionSpec := range config.Dependencies {
-		lib := findLibrary(index, name)
-		if lib == nil {
-			pterm.Error.Println("Library not found:", name)
-			os.Exit(1)
+	registryLock := LockFile{Dependencies: make(map[string]LockedDependency, len(lock.Dependencies))}
+	for name, locked := range lock.Dependencies {
+		if _, isSourced := sourcedDeps[name]; !isSourced {
+			registryLock.Dependencies[name] = locked
 		}
-		version := resolveVersion(lib.Versions, versionSpec)
-		if version == nil {
-			pterm.Error.Println("No matching version for", name, versionSpec)
+	}
+	depPaths, objectFilesContainer, err := compileDependencies(ctx, containerID, registryLock, depsDirTemp, tempDir, trustMode, jobs)
+	if err != nil {
+		pterm.Error.Println(err)
+		os.Exit(1)
+	}
+	lockDirty := !hasLock
+	for name, spec := range sourcedDeps {
+		destDir := filepath.Join(depsDirTemp, name)
+		pinned, hasPinned := lock.Dependencies[name]
+		locked, err := fetchSourceDependency(ctx, name, spec, destDir, pinned, hasPinned)
+		if err != nil {
+			pterm.Error.Println(err)
 			os.Exit(1)
 		}
-		depPath := filepath.Join(depsDirTemp, name, version.Version)
-		if err := os.MkdirAll(depPath, 0755); err != nil {
-			pterm.Error.Println("Failed to create dep path:", err)
+		if !hasPinned || locked != pinned {
+			lockDirty = true
+		}
+		lock.Dependencies[name] = locked
+		srcFile, ext, err := findSourceFile(destDir)
+		if err != nil {
+			pterm.Error.Println(err)
 			os.Exit(1)
 		}
-		fileName := filepath.Base(version.URL)
-		targetFile := filepath.Join(depPath, fileName)
-		if _, err := os.Stat(targetFile); os.IsNotExist(err) {
-			pterm.Info.Println("Downloading", name, version.Version)
-			if err := downloadWithProgress(version.URL, targetFile); err != nil {
-				pterm.Error.Println("Failed to download:", err)
-				os.Exit(1)
-			}
+		containerSrc := strings.Replace(srcFile, tempDir, "/work", 1)
+		containerO := strings.Replace(destDir, tempDir, "/work", 1) + "/lib.o"
+		if err := compileSourceInContainer(ctx, containerID, containerSrc, containerO, []string{}, ext, tempDir); err != nil {
+			os.Exit(1)
 		}
-		depPaths = append(depPaths, depPath)
-		ext := strings.ToLower(filepath.Ext(fileName))
-		containerInput := strings.Replace(targetFile, tempDir, "/work", 1)
-		containerO := strings.Replace(depPath, tempDir, "/work", 1) + "/lib.o"
-		if ext == ".vira" || ext == ".c" || ext == ".cpp" {
-			if err := compileSourceInContainer(ctx, containerID, containerInput, containerO, []string{}, ext, tempDir); err != nil {
-				os.Exit(1)
-			}
-			objectFilesContainer = append(objectFilesContainer, containerO)
+		objectFilesContainer = append(objectFilesContainer, containerO)
+		depPaths = append(depPaths, destDir)
+	}
+	// A source-backed dependency resolves fresh on every compile (it isn't
+	// re-solved through the registry like lock.Dependencies above), so its
+	// pin can change even when virus.lock already existed; persist whenever
+	// that happens, not only when the lock file was missing entirely.
+	if lockDirty {
+		if err := saveLock(lock); err != nil {
+			pterm.Error.Println("Failed to write virus.lock:", err)
+			os.Exit(1)
 		}
+		pterm.Success.Println("Wrote virus.lock")
 	}
 	containerInput := "/work/src/main.vira"
 	containerMainO := "/work/main.o"
@@ -441,26 +603,32 @@ func loadConfig() (Config, error) {
 	if err != nil {
 		return config, err
 	}
-	err = toml.Unmarshal(data, &config)
+	dec := toml.NewDecoder(bytes.NewReader(data))
+	dec.EnableUnmarshalerInterface()
+	err = dec.Decode(&config)
 	return config, err
 }
 
 func saveConfig(config Config) error {
-	data, err := toml.Marshal(config)
-	if err != nil {
+	var buf bytes.Buffer
+	enc := toml.NewEncoder(&buf)
+	enc.EnableMarshalerInterface()
+	if err := enc.Encode(config); err != nil {
 		return err
 	}
-	return os.WriteFile(projectTOML, data, 0644)
+	return os.WriteFile(projectTOML, buf.Bytes(), 0644)
 }
 
-func downloadIndex() (LibraryIndex, error) {
+func downloadIndex(trustMode string) (LibraryIndex, error) {
 	var index LibraryIndex
-	resp, err := http.Get(indexURL)
-	if err != nil {
+	indexPath := filepath.Join(tempDirForDownloads(), "virus.json")
+	if err := downloadFileRaw(indexURL, indexPath); err != nil {
 		return index, err
 	}
-	defer resp.Body.Close()
-	data, err := io.ReadAll(resp.Body)
+	if err := verifySignature(indexURL, indexPath, "", trustMode); err != nil {
+		return index, fmt.Errorf("index signature verification failed: %w", err)
+	}
+	data, err := os.ReadFile(indexPath)
 	if err != nil {
 		return index, err
 	}
@@ -477,31 +645,14 @@ func findLibrary(index LibraryIndex, name string) *Library {
 	return nil
 }
 
-func resolveVersion(versions []Version, spec string) *Version {
-	if spec == "*" {
-		if len(versions) > 0 {
-			return &versions[len(versions)-1]
-		}
-		return nil
-	}
-	if strings.HasPrefix(spec, "^") {
-		prefix := spec[1:]
-		for i := len(versions) - 1; i >= 0; i-- {
-			if strings.HasPrefix(versions[i].Version, prefix) {
-				return &versions[i]
-			}
-		}
-		return nil
-	}
-	for i := len(versions) - 1; i >= 0; i-- {
-		if versions[i].Version == spec {
-			return &versions[i]
-		}
+// downloadWithProgress downloads url to target, rendering a progress bar to
+// w (os.Stderr if w is nil). Callers running several downloads concurrently
+// must pass their own per-worker writer (e.g. a pterm.DefaultMultiPrinter
+// pane) so the bars don't interleave on a shared stderr.
+func downloadWithProgress(url, target, signedBy, trustMode string, w io.Writer) error {
+	if w == nil {
+		w = os.Stderr
 	}
-	return nil
-}
-
-func downloadWithProgress(url, target string) error {
 	resp, err := http.Get(url)
 	if err != nil {
 		return err
@@ -515,13 +666,13 @@ func downloadWithProgress(url, target string) error {
 	bar := progressbar.NewOptions64(
 		resp.ContentLength,
 		progressbar.OptionSetDescription("Downloading"),
-		progressbar.OptionSetWriter(os.Stderr),
+		progressbar.OptionSetWriter(w),
 		progressbar.OptionSetWidth(30),
 		progressbar.OptionThrottle(0),
 		progressbar.OptionShowCount(),
 		progressbar.OptionShowIts(),
 		progressbar.OptionOnCompletion(func() {
-			fmt.Fprint(os.Stderr, "\n")
+			fmt.Fprint(w, "\n")
 		}),
 		progressbar.OptionSpinnerType(14),
 		progressbar.OptionFullWidth(),
@@ -529,8 +680,11 @@ func downloadWithProgress(url, target string) error {
 		progressbar.OptionUseANSICodes(true),
 	)
 	bar.RenderBlank()
-	_, err = io.Copy(io.MultiWriter(f, bar), resp.Body)
-	return err
+	if _, err := io.Copy(io.MultiWriter(f, bar), resp.Body); err != nil {
+		return err
+	}
+	f.Close()
+	return verifySignature(url, target, signedBy, trustMode)
 }
 
 func handleError(sourceFile, errorMsg string) {
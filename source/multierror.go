@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// depError pairs a dependency name with the error it failed with, so a
+// single broken dependency doesn't hide the others in a multi-dep build.
+type depError struct {
+	name string
+	err  error
+}
+
+// multiError accumulates one error per failed dependency and reports all of
+// them together, instead of exiting on the first failure.
+type multiError struct {
+	mu   sync.Mutex
+	errs []depError
+}
+
+func newMultiError() *multiError {
+	return &multiError{}
+}
+
+func (m *multiError) Add(name string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.errs = append(m.errs, depError{name: name, err: err})
+}
+
+func (m *multiError) HasErrors() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.errs) > 0
+}
+
+func (m *multiError) Error() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d dependenc%s failed:\n", len(m.errs), pluralSuffix(len(m.errs)))
+	for _, e := range m.errs {
+		fmt.Fprintf(&b, "  %s: %v\n", e.name, e.err)
+	}
+	return b.String()
+}
+
+func pluralSuffix(n int) string {
+	if n == 1 {
+		return "y"
+	}
+	return "ies"
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/goreleaser/nfpm/v2"
+	_ "github.com/goreleaser/nfpm/v2/apk"
+	_ "github.com/goreleaser/nfpm/v2/deb"
+	"github.com/goreleaser/nfpm/v2/files"
+	_ "github.com/goreleaser/nfpm/v2/rpm"
+	"github.com/pterm/pterm"
+)
+
+const distDir = "dist"
+
+// packageCommand builds the native OS packages declared under [package] in
+// Project.toml from the binary produced by the previous `virus compile`.
+func packageCommand() {
+	config, err := loadConfig()
+	if err != nil {
+		pterm.Error.Println(err)
+		os.Exit(1)
+	}
+	binaryPath := filepath.Join("bin", config.Package.Name)
+	if _, err := os.Stat(binaryPath); err != nil {
+		pterm.Error.Println("Compiled binary not found, run `virus compile` first:", err)
+		os.Exit(1)
+	}
+	if err := packageProject(binaryPath, config.Package); err != nil {
+		pterm.Error.Println(err)
+		os.Exit(1)
+	}
+}
+
+func packageProject(binaryPath string, pkg Package) error {
+	pterm.DefaultSection.Println("Packaging project")
+	if len(pkg.Targets) == 0 {
+		return fmt.Errorf("no [package] targets configured in Project.toml")
+	}
+	if err := os.MkdirAll(distDir, 0755); err != nil {
+		return err
+	}
+
+	info := &nfpm.Info{
+		Name:        pkg.Name,
+		Version:     pkg.Version,
+		Maintainer:  pkg.Maintainer,
+		Description: pkg.Description,
+		License:     pkg.License,
+		Section:     pkg.Section,
+		Overridables: nfpm.Overridables{
+			Depends:   pkg.Depends,
+			Conflicts: pkg.Conflicts,
+			Contents: files.Contents{
+				&files.Content{
+					Source:      binaryPath,
+					Destination: "/usr/bin/" + pkg.Name,
+					FileInfo:    &files.ContentFileInfo{Mode: 0755},
+				},
+			},
+		},
+	}
+	for src, dst := range pkg.Contents {
+		info.Overridables.Contents = append(info.Overridables.Contents, &files.Content{
+			Source:      src,
+			Destination: dst,
+		})
+	}
+
+	for _, target := range pkg.Targets {
+		packager, err := nfpm.Get(target)
+		if err != nil {
+			return fmt.Errorf("unknown package target %q: %w", target, err)
+		}
+		full := nfpm.WithDefaults(info)
+		ext := target
+		if withExt, ok := packager.(nfpm.PackagerWithExtension); ok {
+			ext = withExt.ConventionalExtension()
+		}
+		outPath := filepath.Join(distDir, fmt.Sprintf("%s-%s.%s", pkg.Name, pkg.Version, ext))
+		out, err := os.Create(outPath)
+		if err != nil {
+			return err
+		}
+		if err := packager.Package(full, out); err != nil {
+			out.Close()
+			return fmt.Errorf("failed to build %s package: %w", target, err)
+		}
+		out.Close()
+		pterm.Success.Println("Wrote", outPath)
+	}
+	return nil
+}
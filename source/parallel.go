@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/pterm/pterm"
+	"golang.org/x/sync/errgroup"
+)
+
+// depArtifact is what a successfully processed dependency contributes to the
+// final link step.
+type depArtifact struct {
+	depPath       string
+	objectFile    string
+	hasObjectFile bool
+}
+
+// compileDependencies downloads, verifies, and compiles every dependency in
+// lock concurrently, bounded by jobs workers. Each dependency gets its own
+// live progress bar via pterm.DefaultMultiPrinter instead of interleaving on
+// a single bar. A failure in one dependency does not stop the others; every
+// failure is collected and returned together as a *multiError.
+func compileDependencies(ctx context.Context, containerID string, lock LockFile, depsDirTemp, tempDir, trustMode string, jobs int) ([]string, []string, error) {
+	multi := pterm.DefaultMultiPrinter
+	multi.Start()
+	defer multi.Stop()
+
+	g, _ := errgroup.WithContext(ctx)
+	g.SetLimit(jobs)
+
+	merr := newMultiError()
+	artifacts := make(map[string]depArtifact, len(lock.Dependencies))
+	var mu sync.Mutex
+	var multiMu sync.Mutex
+
+	for name, locked := range lock.Dependencies {
+		name, locked := name, locked
+		g.Go(func() error {
+			multiMu.Lock()
+			pw := multi.NewWriter()
+			multiMu.Unlock()
+			spinner, _ := pterm.DefaultSpinner.WithWriter(pw).Start(fmt.Sprintf("%s %s: starting", name, locked.Version))
+
+			artifact, err := processDependency(ctx, containerID, name, locked, depsDirTemp, tempDir, trustMode, spinner)
+			if err != nil {
+				spinner.Fail(fmt.Sprintf("%s %s: %v", name, locked.Version, err))
+				merr.Add(name, err)
+				return nil
+			}
+			spinner.Success(fmt.Sprintf("%s %s: done", name, locked.Version))
+
+			mu.Lock()
+			artifacts[name] = artifact
+			mu.Unlock()
+			return nil
+		})
+	}
+	g.Wait()
+
+	if merr.HasErrors() {
+		return nil, nil, merr
+	}
+
+	depPaths := make([]string, 0, len(artifacts))
+	objectFilesContainer := make([]string, 0, len(artifacts))
+	for _, artifact := range artifacts {
+		depPaths = append(depPaths, artifact.depPath)
+		if artifact.hasObjectFile {
+			objectFilesContainer = append(objectFilesContainer, artifact.objectFile)
+		}
+	}
+	return depPaths, objectFilesContainer, nil
+}
+
+func processDependency(ctx context.Context, containerID, name string, locked LockedDependency, depsDirTemp, tempDir, trustMode string, spinner *pterm.SpinnerPrinter) (depArtifact, error) {
+	depPath := filepath.Join(depsDirTemp, name, locked.Version)
+	if err := os.MkdirAll(depPath, 0755); err != nil {
+		return depArtifact{}, fmt.Errorf("failed to create dep path: %w", err)
+	}
+	fileName := filepath.Base(locked.URL)
+	targetFile := filepath.Join(depPath, fileName)
+	if _, err := os.Stat(targetFile); os.IsNotExist(err) {
+		spinner.UpdateText(fmt.Sprintf("%s %s: downloading", name, locked.Version))
+		if err := downloadWithProgress(locked.URL, targetFile, locked.SignedBy, trustMode, spinner.Writer); err != nil {
+			return depArtifact{}, fmt.Errorf("failed to download: %w", err)
+		}
+	}
+	sum, err := hashFile(targetFile)
+	if err != nil {
+		return depArtifact{}, fmt.Errorf("failed to hash: %w", err)
+	}
+	if sum != locked.Sha256 {
+		return depArtifact{}, fmt.Errorf("checksum mismatch: expected %s, got %s", locked.Sha256, sum)
+	}
+
+	ext := strings.ToLower(filepath.Ext(fileName))
+	containerInput := strings.Replace(targetFile, tempDir, "/work", 1)
+	containerO := strings.Replace(depPath, tempDir, "/work", 1) + "/lib.o"
+	artifact := depArtifact{depPath: depPath}
+	if ext == ".vira" || ext == ".c" || ext == ".cpp" {
+		spinner.UpdateText(fmt.Sprintf("%s %s: compiling", name, locked.Version))
+		if err := compileSourceInContainer(ctx, containerID, containerInput, containerO, []string{}, ext, tempDir); err != nil {
+			return depArtifact{}, fmt.Errorf("compile failed: %w", err)
+		}
+		artifact.objectFile = containerO
+		artifact.hasObjectFile = true
+	}
+	return artifact, nil
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// semVersion is a parsed (major, minor, patch, prerelease) tuple.
+type semVersion struct {
+	Major, Minor, Patch int
+	Prerelease          string
+}
+
+func parseSemVersion(raw string) (semVersion, error) {
+	core := raw
+	pre := ""
+	if idx := strings.IndexByte(raw, '-'); idx >= 0 {
+		core = raw[:idx]
+		pre = raw[idx+1:]
+	}
+	parts := strings.Split(core, ".")
+	if len(parts) == 0 || len(parts) > 3 {
+		return semVersion{}, fmt.Errorf("invalid version %q", raw)
+	}
+	var nums [3]int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return semVersion{}, fmt.Errorf("invalid version %q: %w", raw, err)
+		}
+		nums[i] = n
+	}
+	return semVersion{Major: nums[0], Minor: nums[1], Patch: nums[2], Prerelease: pre}, nil
+}
+
+// compareSemVersion returns -1, 0 or 1 as a is less than, equal to, or
+// greater than b. A version without a prerelease outranks one with.
+func compareSemVersion(a, b semVersion) int {
+	if a.Major != b.Major {
+		return cmpInt(a.Major, b.Major)
+	}
+	if a.Minor != b.Minor {
+		return cmpInt(a.Minor, b.Minor)
+	}
+	if a.Patch != b.Patch {
+		return cmpInt(a.Patch, b.Patch)
+	}
+	if a.Prerelease == b.Prerelease {
+		return 0
+	}
+	if a.Prerelease == "" {
+		return 1
+	}
+	if b.Prerelease == "" {
+		return -1
+	}
+	return strings.Compare(a.Prerelease, b.Prerelease)
+}
+
+func cmpInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// constraintTerm is one ANDed clause of a Constraint, e.g. "^1.2.3" or
+// ">=1.2, <2.0" parses into two terms.
+type constraintTerm struct {
+	op      string
+	version semVersion
+}
+
+// Constraint is a Cargo-style version requirement: `^X.Y.Z` (same major,
+// >= X.Y.Z), `~X.Y.Z` (same major+minor), `>=`, `<`, `<=`, `>`, exact match,
+// or a comma-separated range such as `>=1.2, <2.0`. An empty constraint (or
+// "*") matches every version.
+type Constraint struct {
+	terms []constraintTerm
+	raw   string
+}
+
+func parseConstraint(spec string) (Constraint, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" || spec == "*" {
+		return Constraint{raw: spec}, nil
+	}
+	var terms []constraintTerm
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		op, verStr := splitConstraintOp(part)
+		ver, err := parseSemVersion(verStr)
+		if err != nil {
+			return Constraint{}, fmt.Errorf("invalid constraint %q: %w", spec, err)
+		}
+		terms = append(terms, constraintTerm{op: op, version: ver})
+	}
+	return Constraint{terms: terms, raw: spec}, nil
+}
+
+func splitConstraintOp(part string) (string, string) {
+	switch {
+	case strings.HasPrefix(part, ">="):
+		return ">=", strings.TrimSpace(part[2:])
+	case strings.HasPrefix(part, "<="):
+		return "<=", strings.TrimSpace(part[2:])
+	case strings.HasPrefix(part, "^"):
+		return "^", strings.TrimSpace(part[1:])
+	case strings.HasPrefix(part, "~"):
+		return "~", strings.TrimSpace(part[1:])
+	case strings.HasPrefix(part, ">"):
+		return ">", strings.TrimSpace(part[1:])
+	case strings.HasPrefix(part, "<"):
+		return "<", strings.TrimSpace(part[1:])
+	default:
+		return "=", part
+	}
+}
+
+func (c Constraint) Matches(v semVersion) bool {
+	for _, t := range c.terms {
+		if !t.matches(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func (t constraintTerm) matches(v semVersion) bool {
+	switch t.op {
+	case "=":
+		return compareSemVersion(v, t.version) == 0
+	case ">=":
+		return compareSemVersion(v, t.version) >= 0
+	case "<=":
+		return compareSemVersion(v, t.version) <= 0
+	case ">":
+		return compareSemVersion(v, t.version) > 0
+	case "<":
+		return compareSemVersion(v, t.version) < 0
+	case "^":
+		return v.Major == t.version.Major && compareSemVersion(v, t.version) >= 0
+	case "~":
+		return v.Major == t.version.Major && v.Minor == t.version.Minor && compareSemVersion(v, t.version) >= 0
+	default:
+		return false
+	}
+}
+
+// sortedVersionsDesc returns versions ordered highest-first.
+func sortedVersionsDesc(versions []Version) []Version {
+	out := make([]Version, len(versions))
+	copy(out, versions)
+	sort.Slice(out, func(i, j int) bool {
+		vi, erri := parseSemVersion(out[i].Version)
+		vj, errj := parseSemVersion(out[j].Version)
+		if erri != nil || errj != nil {
+			return out[i].Version > out[j].Version
+		}
+		return compareSemVersion(vi, vj) > 0
+	})
+	return out
+}
@@ -0,0 +1,97 @@
+package main
+
+import "testing"
+
+func TestParseSemVersion(t *testing.T) {
+	cases := []struct {
+		raw     string
+		want    semVersion
+		wantErr bool
+	}{
+		{raw: "1.2.3", want: semVersion{Major: 1, Minor: 2, Patch: 3}},
+		{raw: "1.2", want: semVersion{Major: 1, Minor: 2, Patch: 0}},
+		{raw: "2.0.0-beta.1", want: semVersion{Major: 2, Minor: 0, Patch: 0, Prerelease: "beta.1"}},
+		{raw: "1.2.3.4", wantErr: true},
+		{raw: "x.y.z", wantErr: true},
+	}
+	for _, tc := range cases {
+		got, err := parseSemVersion(tc.raw)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("parseSemVersion(%q): expected error, got %+v", tc.raw, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseSemVersion(%q): unexpected error: %v", tc.raw, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("parseSemVersion(%q) = %+v, want %+v", tc.raw, got, tc.want)
+		}
+	}
+}
+
+func TestParseConstraintAndMatches(t *testing.T) {
+	cases := []struct {
+		spec    string
+		version string
+		want    bool
+	}{
+		{spec: "^1.2.3", version: "1.2.3", want: true},
+		{spec: "^1.2.3", version: "1.9.0", want: true},
+		{spec: "^1.2.3", version: "1.2.2", want: false},
+		{spec: "^1.2.3", version: "2.0.0", want: false},
+		{spec: "~1.2.3", version: "1.2.9", want: true},
+		{spec: "~1.2.3", version: "1.3.0", want: false},
+		{spec: ">=1.0.0", version: "1.0.0", want: true},
+		{spec: ">=1.0.0", version: "0.9.0", want: false},
+		{spec: "<=1.0.0", version: "1.0.0", want: true},
+		{spec: "<=1.0.0", version: "1.0.1", want: false},
+		{spec: ">1.0.0", version: "1.0.1", want: true},
+		{spec: ">1.0.0", version: "1.0.0", want: false},
+		{spec: "<1.0.0", version: "0.9.0", want: true},
+		{spec: "<1.0.0", version: "1.0.0", want: false},
+		{spec: "1.2.3", version: "1.2.3", want: true},
+		{spec: "1.2.3", version: "1.2.4", want: false},
+		{spec: ">=1.2, <2.0", version: "1.9.0", want: true},
+		{spec: ">=1.2, <2.0", version: "2.0.0", want: false},
+		{spec: "*", version: "0.0.1", want: true},
+		{spec: "", version: "0.0.1", want: true},
+	}
+	for _, tc := range cases {
+		c, err := parseConstraint(tc.spec)
+		if err != nil {
+			t.Fatalf("parseConstraint(%q): unexpected error: %v", tc.spec, err)
+		}
+		v, err := parseSemVersion(tc.version)
+		if err != nil {
+			t.Fatalf("parseSemVersion(%q): unexpected error: %v", tc.version, err)
+		}
+		if got := c.Matches(v); got != tc.want {
+			t.Errorf("Constraint(%q).Matches(%q) = %v, want %v", tc.spec, tc.version, got, tc.want)
+		}
+	}
+}
+
+func TestParseConstraintInvalid(t *testing.T) {
+	if _, err := parseConstraint("^x.y.z"); err == nil {
+		t.Error("parseConstraint(\"^x.y.z\"): expected error, got nil")
+	}
+}
+
+func TestSortedVersionsDesc(t *testing.T) {
+	versions := []Version{
+		{Version: "1.0.0"},
+		{Version: "2.1.0"},
+		{Version: "1.9.0"},
+	}
+	sorted := sortedVersionsDesc(versions)
+	got := []string{sorted[0].Version, sorted[1].Version, sorted[2].Version}
+	want := []string{"2.1.0", "1.9.0", "1.0.0"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("sortedVersionsDesc() = %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/vira-language/virus/pkg/source"
+)
+
+// splitDependencies separates the registry-resolved dependencies (plain
+// version constraints, handled by the existing index + semver pipeline)
+// from the ones that bypass the registry entirely.
+func splitDependencies(deps map[string]DependencySpec) (registry map[string]string, sourced map[string]DependencySpec) {
+	registry = make(map[string]string)
+	sourced = make(map[string]DependencySpec)
+	for name, spec := range deps {
+		if spec.isSourceBacked() {
+			sourced[name] = spec
+		} else {
+			registry[name] = spec.Version
+		}
+	}
+	return registry, sourced
+}
+
+// fetchSourceDependency dispatches spec to the right source.Fetcher and
+// fetches it into destDir. When pinned.Git's Rev is already known (from a
+// previous `virus lock`), it is used instead of re-resolving spec's branch
+// or tag, so the build stays reproducible even if the branch has moved on.
+func fetchSourceDependency(ctx context.Context, name string, spec DependencySpec, destDir string, pinned LockedDependency, hasPinned bool) (LockedDependency, error) {
+	sourceSpec := source.Spec{
+		Name:   name,
+		Git:    spec.Git,
+		Branch: spec.Branch,
+		Tag:    spec.Tag,
+		Rev:    spec.Rev,
+		Path:   spec.Path,
+		URL:    spec.URL,
+		Sha256: spec.Sha256,
+	}
+	var fetcher source.Fetcher
+	switch {
+	case spec.Git != "":
+		if hasPinned && pinned.Rev != "" {
+			sourceSpec.Rev = pinned.Rev
+			sourceSpec.Branch = ""
+			sourceSpec.Tag = ""
+		}
+		fetcher = source.GitFetcher{}
+	case spec.Path != "":
+		fetcher = source.PathFetcher{}
+	default:
+		fetcher = source.TarballFetcher{}
+	}
+	resolved, err := fetcher.Fetch(ctx, sourceSpec, destDir)
+	if err != nil {
+		return LockedDependency{}, fmt.Errorf("failed to fetch %s: %w", name, err)
+	}
+	locked := LockedDependency{Git: spec.Git, Path: spec.Path, URL: spec.URL, Sha256: spec.Sha256}
+	if spec.Git != "" {
+		locked.Rev = resolved
+	} else {
+		locked.Version = resolved
+	}
+	return locked, nil
+}
+
+// findSourceFile locates the single compilation unit inside a fetched
+// source-backed dependency, the same convention a registry artifact follows
+// (one .vira/.c/.cpp file per dependency).
+func findSourceFile(dir string) (path, ext string, err error) {
+	walkErr := filepath.Walk(dir, func(p string, info os.FileInfo, werr error) error {
+		if werr != nil {
+			return werr
+		}
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if path != "" {
+			return nil
+		}
+		e := strings.ToLower(filepath.Ext(p))
+		if e == ".vira" || e == ".c" || e == ".cpp" {
+			path = p
+			ext = e
+		}
+		return nil
+	})
+	if walkErr != nil {
+		return "", "", walkErr
+	}
+	if path == "" {
+		return "", "", fmt.Errorf("no compilable source file found in %s", dir)
+	}
+	return path, ext, nil
+}
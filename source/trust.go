@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/vira-language/virus/pkg/trust"
+)
+
+const (
+	TrustRequired = "required"
+	TrustWarn     = "warn"
+	TrustOff      = "off"
+)
+
+func trustModeOf(config Config) string {
+	if config.Trust == "" {
+		return TrustWarn
+	}
+	return config.Trust
+}
+
+func keyAdd(path string) {
+	info, err := trust.AddKey(path)
+	if err != nil {
+		pterm.Error.Println(err)
+		os.Exit(1)
+	}
+	pterm.Success.Printf("Added key %s (%s)\n", info.Fingerprint, strings.Join(info.Identities, ", "))
+}
+
+func keyRemove(fingerprint string) {
+	if err := trust.RemoveKey(fingerprint); err != nil {
+		pterm.Error.Println(err)
+		os.Exit(1)
+	}
+	pterm.Success.Println("Removed key", fingerprint)
+}
+
+func keyList() {
+	keys, err := trust.ListKeys()
+	if err != nil {
+		pterm.Error.Println(err)
+		os.Exit(1)
+	}
+	if len(keys) == 0 {
+		pterm.Info.Println("No trusted keys")
+		return
+	}
+	for _, key := range keys {
+		pterm.Println(fmt.Sprintf("%s  %s", key.Fingerprint, strings.Join(key.Identities, ", ")))
+	}
+}
+
+// verifySignature fetches the companion .asc detached signature for url and
+// checks it against the local keyring. If expectedFingerprint is non-empty,
+// the artifact must have been signed by that exact key, preventing a
+// key-swap attack against a pinned library. Behavior on failure depends on
+// trustMode: "required" deletes path and returns an error, "warn" logs and
+// continues, "off" skips verification entirely.
+func verifySignature(url, path, expectedFingerprint, trustMode string) error {
+	if trustMode == TrustOff {
+		return nil
+	}
+	keyring, err := trust.LoadKeyring()
+	if err != nil {
+		return handleTrustFailure(path, trustMode, fmt.Errorf("failed to load keyring: %w", err))
+	}
+	sigPath := path + ".asc"
+	if err := downloadFileRaw(url+".asc", sigPath); err != nil {
+		return handleTrustFailure(path, trustMode, fmt.Errorf("failed to fetch signature: %w", err))
+	}
+	defer os.Remove(sigPath)
+	signer, err := trust.VerifyDetached(path, sigPath, keyring)
+	if err != nil {
+		return handleTrustFailure(path, trustMode, err)
+	}
+	if expectedFingerprint != "" && !strings.EqualFold(signer, expectedFingerprint) {
+		return handleTrustFailure(path, trustMode, fmt.Errorf("artifact signed by %s, expected %s", signer, expectedFingerprint))
+	}
+	return nil
+}
+
+func handleTrustFailure(path, trustMode string, cause error) error {
+	if trustMode == TrustRequired {
+		os.Remove(path)
+		return cause
+	}
+	pterm.Warning.Println("Signature verification skipped:", cause)
+	return nil
+}
+
+func tempDirForDownloads() string {
+	return os.TempDir()
+}
+
+func downloadFileRaw(url, path string) error {
+	resp, err := http.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	_, err = io.Copy(out, resp.Body)
+	return err
+}